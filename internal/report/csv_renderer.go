@@ -0,0 +1,43 @@
+package report
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// CSVRenderer renders a Report as CSV, one row per security group.
+type CSVRenderer struct{}
+
+// Render implements Renderer.
+func (c *CSVRenderer) Render(w io.Writer, r *Report) error {
+	writer := csv.NewWriter(w)
+	header := []string{"status", "name", "arn", "in_use", "is_default", "unsafe_port_count", "public_ips", "public_ipv6s", "notes"}
+	if err := writer.Write(header); err != nil {
+		return errors.Wrap(err, "Failed to write CSV header")
+	}
+	for _, row := range r.Rows {
+		record := []string{
+			row.Status,
+			row.Name,
+			row.Arn,
+			strconv.FormatBool(row.InUse),
+			strconv.FormatBool(row.IsDefault),
+			strconv.Itoa(row.UnsafePortCount),
+			strings.Join(row.PublicIps, ";"),
+			strings.Join(row.PublicIpv6s, ";"),
+			strings.Join(row.Notes, ";"),
+		}
+		if err := writer.Write(record); err != nil {
+			return errors.Wrapf(err, "Failed to write CSV row for %v", row.Arn)
+		}
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return errors.Wrap(err, "Failed to flush CSV report")
+	}
+	return nil
+}
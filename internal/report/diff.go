@@ -0,0 +1,97 @@
+package report
+
+import (
+	"reflect"
+	"sort"
+)
+
+// ChangeKind classifies how a security group's row changed between two
+// reports.
+type ChangeKind string
+
+const (
+	ChangeAdded         ChangeKind = "added"
+	ChangeRemoved       ChangeKind = "removed"
+	ChangeStatusChanged ChangeKind = "status_changed"
+	ChangeNotesChanged  ChangeKind = "notes_changed"
+	ChangeIPsChanged    ChangeKind = "ips_changed"
+	ChangeUnchanged     ChangeKind = "unchanged"
+)
+
+// RowChange describes what changed for a single security group ARN between
+// two reports. FromStatus/ToStatus are populated whenever the ARN is present
+// in both reports; they differ only for ChangeStatusChanged.
+type RowChange struct {
+	Arn        string
+	Kind       ChangeKind
+	FromStatus string
+	ToStatus   string
+	// Row is the group's current row, or its last-known row when Kind is
+	// ChangeRemoved.
+	Row Row
+}
+
+// DiffReport is the result of comparing a previous and current Report.
+type DiffReport struct {
+	Previous *Metadata
+	Current  *Metadata
+	Changes  []RowChange
+}
+
+// Diff compares prev and curr by ARN and classifies each security group as
+// added, removed, status_changed, notes_changed, ips_changed, or unchanged.
+// When an ARN matches more than one kind of change, status_changed takes
+// precedence over notes_changed, which takes precedence over ips_changed.
+func Diff(prev, curr *Report) *DiffReport {
+	prevByArn := rowsByArn(prev)
+	currByArn := rowsByArn(curr)
+
+	changes := []RowChange{}
+	for arn, currRow := range currByArn {
+		prevRow, existed := prevByArn[arn]
+		if !existed {
+			changes = append(changes, RowChange{Arn: arn, Kind: ChangeAdded, Row: currRow})
+			continue
+		}
+		changes = append(changes, RowChange{Arn: arn, Kind: classify(prevRow, currRow), FromStatus: prevRow.Status, ToStatus: currRow.Status, Row: currRow})
+	}
+	for arn, prevRow := range prevByArn {
+		if _, stillPresent := currByArn[arn]; !stillPresent {
+			changes = append(changes, RowChange{Arn: arn, Kind: ChangeRemoved, Row: prevRow})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool { return changes[i].Arn < changes[j].Arn })
+
+	var prevMetadata, currMetadata *Metadata
+	if prev != nil {
+		prevMetadata = prev.Metadata
+	}
+	if curr != nil {
+		currMetadata = curr.Metadata
+	}
+	return &DiffReport{Previous: prevMetadata, Current: currMetadata, Changes: changes}
+}
+
+func classify(prev, curr Row) ChangeKind {
+	if prev.Status != curr.Status {
+		return ChangeStatusChanged
+	}
+	if !reflect.DeepEqual(prev.Notes, curr.Notes) {
+		return ChangeNotesChanged
+	}
+	if !reflect.DeepEqual(prev.PublicIps, curr.PublicIps) || !reflect.DeepEqual(prev.PublicIpv6s, curr.PublicIpv6s) {
+		return ChangeIPsChanged
+	}
+	return ChangeUnchanged
+}
+
+func rowsByArn(r *Report) map[string]Row {
+	byArn := map[string]Row{}
+	if r == nil {
+		return byArn
+	}
+	for _, row := range r.Rows {
+		byArn[row.Arn] = row
+	}
+	return byArn
+}
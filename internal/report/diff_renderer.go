@@ -0,0 +1,162 @@
+package report
+
+import (
+	"encoding/json"
+	"html/template"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// DiffRenderer renders a DiffReport to an output stream in a particular
+// format, mirroring Renderer for plain Reports.
+type DiffRenderer interface {
+	RenderDiff(w io.Writer, d *DiffReport) error
+}
+
+// DiffRenderers maps a format name, as accepted by sgcheckup diff's
+// --format flag, to the DiffRenderer that produces it.
+var DiffRenderers = map[string]DiffRenderer{
+	"html":  &HTMLDiffRenderer{},
+	"json":  &JSONDiffRenderer{},
+	"sarif": &SarifDiffRenderer{},
+}
+
+// DiffRendererFor looks up a registered DiffRenderer by format name.
+func DiffRendererFor(format string) (DiffRenderer, error) {
+	renderer, ok := DiffRenderers[format]
+	if !ok {
+		return nil, errors.Errorf("Unknown diff output format %q", format)
+	}
+	return renderer, nil
+}
+
+// JSONDiffRenderer renders a DiffReport as indented JSON.
+type JSONDiffRenderer struct{}
+
+// RenderDiff implements DiffRenderer.
+func (j *JSONDiffRenderer) RenderDiff(w io.Writer, d *DiffReport) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(d); err != nil {
+		return errors.Wrap(err, "Failed to render JSON diff")
+	}
+	return nil
+}
+
+var htmlDiffTemplate = template.Must(template.New("diff").Parse(`<!DOCTYPE html>
+<html>
+<head><title>sgCheckup Diff</title></head>
+<body>
+<h1>sgCheckup Diff</h1>
+<table border="1" cellpadding="4">
+<tr><th>Change</th><th>Arn</th><th>Name</th><th>From</th><th>To</th></tr>
+{{range .Changes}}
+{{if ne .Kind "unchanged"}}
+<tr>
+<td>{{.Kind}}</td>
+<td>{{.Arn}}</td>
+<td>{{.Row.Name}}</td>
+<td>{{.FromStatus}}</td>
+<td>{{.ToStatus}}</td>
+</tr>
+{{end}}
+{{end}}
+</table>
+</body>
+</html>
+`))
+
+// HTMLDiffRenderer renders a DiffReport as an HTML page highlighting what
+// changed between two scans.
+type HTMLDiffRenderer struct{}
+
+// RenderDiff implements DiffRenderer.
+func (h *HTMLDiffRenderer) RenderDiff(w io.Writer, d *DiffReport) error {
+	if err := htmlDiffTemplate.Execute(w, d); err != nil {
+		return errors.Wrap(err, "Failed to render HTML diff")
+	}
+	return nil
+}
+
+// SarifDiffRenderer renders a DiffReport as SARIF 2.1.0, tagging each result
+// with a baselineState of "new", "unchanged", or "absent" so CI can fail
+// only on newly-introduced risk.
+type SarifDiffRenderer struct{}
+
+// RenderDiff implements DiffRenderer.
+func (s *SarifDiffRenderer) RenderDiff(w io.Writer, d *DiffReport) error {
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{
+			{
+				Tool: sarifTool{
+					Driver: sarifDriver{
+						Name:           "sgCheckup",
+						Rules:          sarifRules,
+						InformationURI: "https://github.com/ryuheechul/sgCheckup",
+					},
+				},
+				Results: sarifDiffResults(d.Changes),
+			},
+		},
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(log); err != nil {
+		return errors.Wrap(err, "Failed to render SARIF diff")
+	}
+	return nil
+}
+
+func sarifDiffResults(changes []RowChange) []sarifResult {
+	results := make([]sarifResult, 0, len(changes))
+	for _, change := range changes {
+		if change.Row.Status == "green" {
+			continue
+		}
+		baselineState := sarifBaselineStateFor(change.Kind)
+		if change.Kind == ChangeStatusChanged && change.FromStatus == "green" {
+			// A group that was green had no result in the baseline, so its
+			// risk is new even though the group itself is not.
+			baselineState = "new"
+		}
+		results = append(results, sarifResult{
+			RuleID:        sarifRuleFor(change.Row),
+			Level:         sarifLevelFor(change.Row.Status),
+			Message:       sarifMessage{Text: sarifDiffMessage(change)},
+			Locations:     []sarifLocation{{LogicalLocations: []sarifLogicalLocation{{FullyQualifiedName: change.Arn}}}},
+			BaselineState: baselineState,
+		})
+	}
+	return results
+}
+
+func sarifBaselineStateFor(kind ChangeKind) string {
+	switch kind {
+	case ChangeAdded:
+		return "new"
+	case ChangeRemoved:
+		return "absent"
+	default:
+		return "unchanged"
+	}
+}
+
+func sarifDiffMessage(change RowChange) string {
+	switch change.Kind {
+	case ChangeStatusChanged:
+		return "Status changed from " + change.FromStatus + " to " + change.ToStatus
+	case ChangeAdded:
+		return "New security group"
+	case ChangeRemoved:
+		return "Security group no longer present"
+	case ChangeNotesChanged:
+		return "Findings changed"
+	case ChangeIPsChanged:
+		return "Public IP addresses changed"
+	default:
+		return "No change"
+	}
+}
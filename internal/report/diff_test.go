@@ -0,0 +1,90 @@
+package report
+
+import "testing"
+
+func TestDiffClassifiesAndOrdersByArn(t *testing.T) {
+	prev := &Report{Rows: []Row{
+		{Arn: "arn:b", Name: "b", Status: "green", Notes: []string{"No public IP addresses found"}},
+		{Arn: "arn:d", Name: "d", Status: "red", Notes: []string{"Not in use"}},
+		{Arn: "arn:a", Name: "a", Status: "yellow", PublicIps: []string{"1.1.1.1"}},
+	}}
+	curr := &Report{Rows: []Row{
+		{Arn: "arn:b", Name: "b", Status: "red", Notes: []string{"Allows TCP from 0.0.0.0/0 on ports (22)"}},
+		{Arn: "arn:a", Name: "a", Status: "yellow", PublicIps: []string{"2.2.2.2"}},
+		{Arn: "arn:c", Name: "c", Status: "green"},
+	}}
+
+	d := Diff(prev, curr)
+
+	wantArns := []string{"arn:a", "arn:b", "arn:c", "arn:d"}
+	if len(d.Changes) != len(wantArns) {
+		t.Fatalf("expected %d changes, got %d: %+v", len(wantArns), len(d.Changes), d.Changes)
+	}
+	for i, want := range wantArns {
+		if d.Changes[i].Arn != want {
+			t.Errorf("changes not sorted by arn: index %d expected %q, got %q", i, want, d.Changes[i].Arn)
+		}
+	}
+
+	byArn := map[string]RowChange{}
+	for _, c := range d.Changes {
+		byArn[c.Arn] = c
+	}
+	if got := byArn["arn:a"].Kind; got != ChangeIPsChanged {
+		t.Errorf("arn:a: expected %v, got %v", ChangeIPsChanged, got)
+	}
+	if got := byArn["arn:b"].Kind; got != ChangeStatusChanged {
+		t.Errorf("arn:b: expected %v, got %v", ChangeStatusChanged, got)
+	}
+	if got := byArn["arn:c"].Kind; got != ChangeAdded {
+		t.Errorf("arn:c: expected %v, got %v", ChangeAdded, got)
+	}
+	if got := byArn["arn:d"].Kind; got != ChangeRemoved {
+		t.Errorf("arn:d: expected %v, got %v", ChangeRemoved, got)
+	}
+}
+
+func TestClassifyPrecedence(t *testing.T) {
+	base := Row{Status: "yellow", Notes: []string{"Not in use"}, PublicIps: []string{"1.1.1.1"}}
+
+	statusChanged := base
+	statusChanged.Status = "red"
+	statusChanged.Notes = []string{"Allows TCP from 0.0.0.0/0 on ports (22)"}
+	statusChanged.PublicIps = []string{"2.2.2.2"}
+	if got := classify(base, statusChanged); got != ChangeStatusChanged {
+		t.Errorf("expected %v, got %v", ChangeStatusChanged, got)
+	}
+
+	notesChanged := base
+	notesChanged.Notes = []string{"Uses a lot of IP Ranges"}
+	notesChanged.PublicIps = []string{"2.2.2.2"}
+	if got := classify(base, notesChanged); got != ChangeNotesChanged {
+		t.Errorf("expected %v, got %v", ChangeNotesChanged, got)
+	}
+
+	ipsChanged := base
+	ipsChanged.PublicIps = []string{"2.2.2.2"}
+	if got := classify(base, ipsChanged); got != ChangeIPsChanged {
+		t.Errorf("expected %v, got %v", ChangeIPsChanged, got)
+	}
+
+	if got := classify(base, base); got != ChangeUnchanged {
+		t.Errorf("expected %v, got %v", ChangeUnchanged, got)
+	}
+}
+
+func TestSarifDiffResultsSkipsGreenRows(t *testing.T) {
+	changes := []RowChange{
+		{Arn: "arn:red", Kind: ChangeStatusChanged, FromStatus: "yellow", ToStatus: "red", Row: Row{Arn: "arn:red", Status: "red"}},
+		{Arn: "arn:green", Kind: ChangeUnchanged, Row: Row{Arn: "arn:green", Status: "green"}},
+	}
+
+	results := sarifDiffResults(changes)
+
+	if len(results) != 1 {
+		t.Fatalf("expected 1 result (green row skipped), got %d: %+v", len(results), results)
+	}
+	if results[0].Locations[0].LogicalLocations[0].FullyQualifiedName != "arn:red" {
+		t.Errorf("expected the surviving result to be for arn:red, got %+v", results[0])
+	}
+}
@@ -0,0 +1,45 @@
+package report
+
+import (
+	"html/template"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head><title>sgCheckup Report</title></head>
+<body>
+<h1>sgCheckup Report</h1>
+{{if .Metadata}}
+<p>Account {{.Metadata.Account}}, generated {{.Metadata.Generated}} from data imported {{.Metadata.Imported}}</p>
+{{end}}
+<table border="1" cellpadding="4">
+<tr><th>Status</th><th>Name</th><th>Arn</th><th>Public IPs</th><th>Notes</th></tr>
+{{range .Rows}}
+<tr style="background-color: {{.Status}}">
+<td>{{.Status}}</td>
+<td>{{.Name}}</td>
+<td>{{.Arn}}</td>
+<td>{{range .PublicIps}}{{.}}<br>{{end}}{{range .PublicIpv6s}}{{.}}<br>{{end}}</td>
+<td><ul>{{range .Notes}}<li>{{.}}</li>{{end}}</ul></td>
+</tr>
+{{end}}
+</table>
+</body>
+</html>
+`))
+
+// HTMLRenderer renders a Report as the self-contained HTML page sgCheckup
+// has always produced.
+type HTMLRenderer struct{}
+
+// Render implements Renderer.
+func (h *HTMLRenderer) Render(w io.Writer, r *Report) error {
+	err := htmlReportTemplate.Execute(w, r)
+	if err != nil {
+		return errors.Wrap(err, "Failed to render HTML report")
+	}
+	return nil
+}
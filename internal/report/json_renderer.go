@@ -0,0 +1,22 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// JSONRenderer renders a Report as indented JSON, suitable for piping into
+// other tooling or for sgcheckup diff to consume later.
+type JSONRenderer struct{}
+
+// Render implements Renderer.
+func (j *JSONRenderer) Render(w io.Writer, r *Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(r); err != nil {
+		return errors.Wrap(err, "Failed to render JSON report")
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package report
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// SaveJSON persists a report to dir, keyed by account and generation time,
+// so a later run can load it back for sgcheckup diff.
+func SaveJSON(r *Report, dir string) (string, error) {
+	bytes, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return "", errors.Wrap(err, "Failed to marshal report")
+	}
+	path := filepath.Join(dir, reportFilename(r))
+	if err := ioutil.WriteFile(path, bytes, 0644); err != nil {
+		return "", errors.Wrapf(err, "Failed to write report to %v", path)
+	}
+	return path, nil
+}
+
+// LoadJSON reads back a report saved by SaveJSON, or produced by
+// sgcheckup --format json.
+func LoadJSON(path string) (*Report, error) {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to read report from %v", path)
+	}
+	r := &Report{}
+	if err := json.Unmarshal(bytes, r); err != nil {
+		return nil, errors.Wrapf(err, "Failed to parse report from %v", path)
+	}
+	return r, nil
+}
+
+func reportFilename(r *Report) string {
+	if r.Metadata == nil {
+		return "report.json"
+	}
+	return fmt.Sprintf("%v-%v.json", r.Metadata.Account, r.Metadata.Generated.Unix())
+}
+
+const createReportsTable = `
+create table if not exists sgcheckup_reports (
+	account text not null,
+	generated_at timestamptz not null,
+	report jsonb not null,
+	primary key (account, generated_at)
+)`
+
+const upsertReport = `
+insert into sgcheckup_reports (account, generated_at, report)
+values ($1, $2, $3)
+on conflict (account, generated_at) do update set report = excluded.report`
+
+// SaveToDB persists a report into the sgcheckup_reports table, creating it
+// if this is the first report saved against db, keyed the same way SaveJSON
+// keys its files: by account and generation time.
+func SaveToDB(db *sql.DB, r *Report) error {
+	if r.Metadata == nil {
+		return errors.New("Cannot persist a report with no metadata")
+	}
+	if _, err := db.Exec(createReportsTable); err != nil {
+		return errors.Wrap(err, "Failed to create sgcheckup_reports table")
+	}
+	bytes, err := json.Marshal(r)
+	if err != nil {
+		return errors.Wrap(err, "Failed to marshal report")
+	}
+	_, err = db.Exec(upsertReport, r.Metadata.Account, r.Metadata.Generated, bytes)
+	if err != nil {
+		return errors.Wrap(err, "Failed to upsert report")
+	}
+	return nil
+}
+
+// LoadFromDB loads the most recently generated report for an account from
+// the sgcheckup_reports table.
+func LoadFromDB(db *sql.DB, account string) (*Report, error) {
+	row := db.QueryRow(
+		"select report from sgcheckup_reports where account = $1 order by generated_at desc limit 1",
+		account,
+	)
+	var bytes []byte
+	if err := row.Scan(&bytes); err != nil {
+		return nil, errors.Wrapf(err, "Failed to load most recent report for account %v", account)
+	}
+	r := &Report{}
+	if err := json.Unmarshal(bytes, r); err != nil {
+		return nil, errors.Wrap(err, "Failed to parse persisted report")
+	}
+	return r, nil
+}
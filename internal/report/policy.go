@@ -0,0 +1,167 @@
+package report
+
+import (
+	"io/ioutil"
+
+	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/vm"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+// PolicyContext is the set of facts about a security group that a policy
+// rule's expression is evaluated against.
+type PolicyContext struct {
+	InUse            bool
+	IsDefault        bool
+	IsRestricted     bool
+	InternalOnly     bool
+	UnsafePorts      int
+	PublicIPs        []string
+	LargePublicBlock bool
+	LargeRangeCount  bool
+	Region           string
+	Account          string
+	Tags             map[string]string
+}
+
+// PolicyRule is a single row of a Policy: a boolean expression evaluated
+// against a PolicyContext, and the status/note to apply when it matches.
+type PolicyRule struct {
+	Name   string `yaml:"name"`
+	Expr   string `yaml:"expr"`
+	Status string `yaml:"status"`
+	Note   string `yaml:"note"`
+}
+
+// Policy is an ordered list of rules. The first rule whose expression
+// evaluates to true for a given PolicyContext decides that group's status.
+type Policy struct {
+	Rules []PolicyRule `yaml:"rules"`
+}
+
+type compiledRule struct {
+	PolicyRule
+	program *vm.Program
+}
+
+// LoadPolicy reads a YAML policy file, such as one supplied via --policy.
+func LoadPolicy(path string) (*Policy, error) {
+	bytes, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Failed to read policy file %v", path)
+	}
+	policy := &Policy{}
+	if err := yaml.Unmarshal(bytes, policy); err != nil {
+		return nil, errors.Wrapf(err, "Failed to parse policy file %v", path)
+	}
+	return policy, nil
+}
+
+// compile precompiles each rule's expression so CompiledPolicy can run a
+// Policy against many rows without recompiling per row.
+func (p *Policy) compile() ([]compiledRule, error) {
+	compiled := make([]compiledRule, 0, len(p.Rules))
+	for _, rule := range p.Rules {
+		program, err := expr.Compile(rule.Expr, expr.Env(PolicyContext{}), expr.AsBool())
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to compile policy rule %q", rule.Name)
+		}
+		compiled = append(compiled, compiledRule{PolicyRule: rule, program: program})
+	}
+	return compiled, nil
+}
+
+// CompiledPolicy is a Policy whose rule expressions have already been
+// compiled, so Evaluate can run against many rows without recompiling per row.
+type CompiledPolicy struct {
+	rules []compiledRule
+}
+
+// Compile precompiles policy's rule expressions into a CompiledPolicy. Callers
+// evaluating many rows against the same policy, such as Generate, should
+// compile once up front and reuse it rather than calling EvaluatePolicy per row.
+func (p *Policy) Compile() (*CompiledPolicy, error) {
+	rules, err := p.compile()
+	if err != nil {
+		return nil, err
+	}
+	return &CompiledPolicy{rules: rules}, nil
+}
+
+// Evaluate runs a CompiledPolicy's rules, in order, against ctx and returns
+// the status and optional note of the first rule that matches.
+func (cp *CompiledPolicy) Evaluate(ctx *PolicyContext) (status string, note string, err error) {
+	for _, rule := range cp.rules {
+		result, err := expr.Run(rule.program, *ctx)
+		if err != nil {
+			return "", "", errors.Wrapf(err, "Failed to evaluate policy rule %q", rule.Name)
+		}
+		if matched, ok := result.(bool); ok && matched {
+			return rule.Status, rule.Note, nil
+		}
+	}
+	return "", "", errors.New("No policy rule matched; policies must end with a catch-all rule")
+}
+
+// EvaluatePolicy compiles policy and runs it once against ctx. Go callers
+// evaluating many rows against the same policy should call Policy.Compile
+// once and reuse the resulting CompiledPolicy instead, to avoid recompiling
+// every rule's expression per row.
+func EvaluatePolicy(policy *Policy, ctx *PolicyContext) (status string, note string, err error) {
+	compiled, err := policy.Compile()
+	if err != nil {
+		return "", "", err
+	}
+	return compiled.Evaluate(ctx)
+}
+
+// DefaultPolicy reproduces sgCheckup's built-in red/yellow/green classification
+// as an ordered rule list, so it can run through the same EvaluatePolicy path
+// that --policy-supplied rules do.
+func DefaultPolicy() *Policy {
+	return &Policy{
+		Rules: []PolicyRule{
+			{
+				Name:   "default-group-in-use-exposed",
+				Expr:   "IsDefault && InUse && !(IsRestricted || InternalOnly || len(PublicIPs) == 0)",
+				Status: "red",
+			},
+			{
+				Name:   "default-group-in-use",
+				Expr:   "IsDefault && InUse",
+				Status: "yellow",
+			},
+			{
+				Name:   "default-group-unused-restricted",
+				Expr:   "IsDefault && !InUse && IsRestricted",
+				Status: "green",
+			},
+			{
+				Name:   "default-group-unused",
+				Expr:   "IsDefault && !InUse",
+				Status: "yellow",
+			},
+			{
+				Name:   "custom-group-restricted-or-safe",
+				Expr:   "!IsDefault && InUse && (IsRestricted || (!LargePublicBlock && !LargeRangeCount && UnsafePorts == 0))",
+				Status: "green",
+			},
+			{
+				Name:   "custom-group-in-use-no-public-ips",
+				Expr:   "!IsDefault && InUse && len(PublicIPs) == 0",
+				Status: "yellow",
+			},
+			{
+				Name:   "custom-group-in-use-risky",
+				Expr:   "!IsDefault && InUse",
+				Status: "red",
+			},
+			{
+				Name:   "custom-group-unused",
+				Expr:   "!IsDefault",
+				Status: "yellow",
+			},
+		},
+	}
+}
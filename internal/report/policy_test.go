@@ -0,0 +1,102 @@
+package report
+
+import "testing"
+
+func TestDefaultPolicyEvaluate(t *testing.T) {
+	cases := []struct {
+		name   string
+		ctx    PolicyContext
+		status string
+	}{
+		{
+			name:   "default group in use and exposed",
+			ctx:    PolicyContext{IsDefault: true, InUse: true, PublicIPs: []string{"1.2.3.4"}},
+			status: "red",
+		},
+		{
+			name:   "default group in use but restricted",
+			ctx:    PolicyContext{IsDefault: true, InUse: true, IsRestricted: true, PublicIPs: []string{"1.2.3.4"}},
+			status: "yellow",
+		},
+		{
+			name:   "default group unused and restricted",
+			ctx:    PolicyContext{IsDefault: true, IsRestricted: true},
+			status: "green",
+		},
+		{
+			name:   "default group unused and open",
+			ctx:    PolicyContext{IsDefault: true},
+			status: "yellow",
+		},
+		{
+			name:   "custom group in use and restricted",
+			ctx:    PolicyContext{InUse: true, IsRestricted: true, PublicIPs: []string{"1.2.3.4"}},
+			status: "green",
+		},
+		{
+			name:   "custom group in use with no large blocks or unsafe ports",
+			ctx:    PolicyContext{InUse: true, PublicIPs: []string{"1.2.3.4"}},
+			status: "green",
+		},
+		{
+			name:   "custom group in use with an unsafe port but no public ips",
+			ctx:    PolicyContext{InUse: true, UnsafePorts: 1},
+			status: "yellow",
+		},
+		{
+			name:   "custom group in use with an unsafe port exposed publicly",
+			ctx:    PolicyContext{InUse: true, UnsafePorts: 1, PublicIPs: []string{"1.2.3.4"}},
+			status: "red",
+		},
+		{
+			name:   "custom group unused",
+			ctx:    PolicyContext{},
+			status: "yellow",
+		},
+	}
+
+	policy := DefaultPolicy()
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			status, _, err := EvaluatePolicy(policy, &tc.ctx)
+			if err != nil {
+				t.Fatalf("EvaluatePolicy returned error: %v", err)
+			}
+			if status != tc.status {
+				t.Errorf("expected status %q, got %q", tc.status, status)
+			}
+		})
+	}
+}
+
+func TestEvaluatePolicyNoRuleMatches(t *testing.T) {
+	policy := &Policy{Rules: []PolicyRule{{Name: "never", Expr: "false", Status: "red"}}}
+	_, _, err := EvaluatePolicy(policy, &PolicyContext{})
+	if err == nil {
+		t.Fatal("expected an error when no policy rule matches")
+	}
+}
+
+func TestCompiledPolicyReusableAcrossRows(t *testing.T) {
+	policy := DefaultPolicy()
+	compiled, err := policy.Compile()
+	if err != nil {
+		t.Fatalf("Compile returned error: %v", err)
+	}
+	restricted := PolicyContext{InUse: true, IsRestricted: true}
+	status, _, err := compiled.Evaluate(&restricted)
+	if err != nil {
+		t.Fatalf("Evaluate returned error: %v", err)
+	}
+	if status != "green" {
+		t.Errorf("expected status %q, got %q", "green", status)
+	}
+	exposed := PolicyContext{InUse: true, UnsafePorts: 1, PublicIPs: []string{"1.2.3.4"}}
+	status, _, err = compiled.Evaluate(&exposed)
+	if err != nil {
+		t.Fatalf("Evaluate returned error: %v", err)
+	}
+	if status != "red" {
+		t.Errorf("expected status %q, got %q", "red", status)
+	}
+}
@@ -0,0 +1,90 @@
+// Package prom exposes a sgCheckup report as Prometheus metrics, either for
+// live scraping over HTTP or as a one-shot textfile for node_exporter.
+package prom
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"goldfiglabs.com/sgcheckup/internal/report"
+)
+
+const namespace = "sgcheckup"
+
+var statuses = []string{"red", "yellow", "green"}
+
+var (
+	statusDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "security_group", "status"),
+		"Indicator (1) for the current status color of a security group, 0 otherwise",
+		[]string{"arn", "name", "region", "account", "status"}, nil,
+	)
+	publicIPsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "security_group", "public_ips"),
+		"Number of public IP addresses associated with a security group",
+		[]string{"arn", "name", "region", "account"}, nil,
+	)
+	unsafePortCountDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "security_group", "unsafe_port_count"),
+		"Number of ports, not counting safe-listed ones, left open to the world on a security group",
+		[]string{"arn", "name", "region", "account"}, nil,
+	)
+	generatedDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "report", "generated_timestamp_seconds"),
+		"Unix timestamp of when the underlying report was generated",
+		nil, nil,
+	)
+	groupsTotalDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "groups_total"),
+		"Number of security groups in the report by status",
+		[]string{"status"}, nil,
+	)
+)
+
+// Collector adapts a *report.Report into a prometheus.Collector so its
+// findings can be scraped or dumped to a textfile.
+type Collector struct {
+	report *report.Report
+}
+
+// NewCollector wraps a report for Prometheus collection.
+func NewCollector(r *report.Report) *Collector {
+	return &Collector{report: r}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- statusDesc
+	ch <- publicIPsDesc
+	ch <- unsafePortCountDesc
+	ch <- generatedDesc
+	ch <- groupsTotalDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	counts := map[string]int{}
+	for _, row := range c.report.Rows {
+		region := report.ArnRegion(row.Arn)
+		account := report.ArnAccount(row.Arn)
+		for _, status := range statuses {
+			value := 0.0
+			if row.Status == status {
+				value = 1.0
+			}
+			ch <- prometheus.MustNewConstMetric(statusDesc, prometheus.GaugeValue, value,
+				row.Arn, row.Name, region, account, status)
+		}
+		ch <- prometheus.MustNewConstMetric(publicIPsDesc, prometheus.GaugeValue, float64(len(row.PublicIps)+len(row.PublicIpv6s)),
+			row.Arn, row.Name, region, account)
+		ch <- prometheus.MustNewConstMetric(unsafePortCountDesc, prometheus.GaugeValue, float64(row.UnsafePortCount),
+			row.Arn, row.Name, region, account)
+		counts[row.Status]++
+	}
+	if c.report.Metadata != nil {
+		ch <- prometheus.MustNewConstMetric(generatedDesc, prometheus.GaugeValue,
+			float64(c.report.Metadata.Generated.Unix()))
+	}
+	for _, status := range statuses {
+		ch <- prometheus.MustNewConstMetric(groupsTotalDesc, prometheus.GaugeValue, float64(counts[status]), status)
+	}
+}
@@ -0,0 +1,49 @@
+package prom
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+
+	"goldfiglabs.com/sgcheckup/internal/report"
+)
+
+// WriteTextfile renders a report as a node_exporter textfile collector file,
+// writing it atomically so a concurrent scrape never observes a partial file.
+func WriteTextfile(r *report.Report, path string) error {
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(NewCollector(r)); err != nil {
+		return errors.Wrap(err, "Failed to register report collector")
+	}
+	metricFamilies, err := registry.Gather()
+	if err != nil {
+		return errors.Wrap(err, "Failed to gather report metrics")
+	}
+	dir := filepath.Dir(path)
+	tmp, err := ioutil.TempFile(dir, filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return errors.Wrap(err, "Failed to create temp file for textfile collector")
+	}
+	defer os.Remove(tmp.Name())
+	encoder := expfmt.NewEncoder(tmp, expfmt.FmtText)
+	for _, mf := range metricFamilies {
+		if err := encoder.Encode(mf); err != nil {
+			tmp.Close()
+			return errors.Wrap(err, "Failed to encode metric family")
+		}
+	}
+	if err := tmp.Close(); err != nil {
+		return errors.Wrap(err, "Failed to close temp file for textfile collector")
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return errors.Wrap(err, "Failed to set permissions on textfile collector output")
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return errors.Wrap(err, "Failed to move textfile collector output into place")
+	}
+	return nil
+}
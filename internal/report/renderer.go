@@ -0,0 +1,30 @@
+package report
+
+import (
+	"io"
+
+	"github.com/pkg/errors"
+)
+
+// Renderer renders a Report to an output stream in a particular format.
+type Renderer interface {
+	Render(w io.Writer, r *Report) error
+}
+
+// Renderers maps a format name, as accepted by the CLI's --format flag, to
+// the Renderer that produces it.
+var Renderers = map[string]Renderer{
+	"html":  &HTMLRenderer{},
+	"json":  &JSONRenderer{},
+	"csv":   &CSVRenderer{},
+	"sarif": &SarifRenderer{},
+}
+
+// RendererFor looks up a registered Renderer by format name.
+func RendererFor(format string) (Renderer, error) {
+	renderer, ok := Renderers[format]
+	if !ok {
+		return nil, errors.Errorf("Unknown output format %q", format)
+	}
+	return renderer, nil
+}
@@ -2,6 +2,7 @@ package report
 
 import (
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"sort"
@@ -16,50 +17,106 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// Protocol identifiers match the values AWS uses for an IpPermission's
+// IpProtocol: "tcp", "udp", "icmp" (icmpv6 is folded into "icmp"), and "-1"
+// for all traffic.
+const (
+	protocolTCP  = "tcp"
+	protocolUDP  = "udp"
+	protocolICMP = "icmp"
+	protocolAny  = "-1"
+)
+
+var allProtocols = []string{protocolTCP, protocolUDP, protocolICMP, protocolAny}
+
+// protocolPortRanges holds a protocol's open port ranges, one multirange
+// string per address family, as produced by queries/security_groups.sql.
+type protocolPortRanges struct {
+	v4 string
+	v6 string
+}
+
 type securityGroupRow struct {
-	arn                string
-	groupName          string
-	ips                []string
-	inUse              bool
-	isDefault          bool
-	portRanges         []string
-	isLargePublicBlock bool
-	largeRangeCount    bool
-	isRestricted       bool
-	internalOnly       bool
+	arn                  string
+	groupName            string
+	ips                  []string
+	ipv6s                []string
+	inUse                bool
+	isDefault            bool
+	portRanges           map[string]protocolPortRanges
+	isLargePublicBlockV4 bool
+	isLargePublicBlockV6 bool
+	largeRangeCount      bool
+	isRestricted         bool
+	internalOnly         bool
+	tags                 map[string]string
 }
 
-func (r *securityGroupRow) isProblematic() bool {
-	if r.largeRangeCount {
-		return true
-	}
-	if r.isLargePublicBlock {
-		return true
-	}
-	return false
+// protocolUnsafePorts is a protocol/address-family pair that has unsafe
+// ports open to the world, and the ports themselves.
+type protocolUnsafePorts struct {
+	protocol string
+	family   string
+	ports    *multirange.MultiRange
 }
 
-func (r *securityGroupRow) unsafePorts(safePorts []int) (*multirange.MultiRange, error) {
-	if len(r.portRanges) > 0 {
-		mr, err := multirange.FromString(r.portRanges[0])
-		if err != nil {
-			return nil, errors.Wrapf(err, "Failed to parse port range %v", r.portRanges)
-		}
-		for _, port := range safePorts {
-			mr.RemoveElement(port)
+// unsafePorts evaluates each protocol and address family independently
+// against its own safe-port list, e.g. UDP/53 can be safe-listed for DNS
+// resolvers without also safe-listing TCP/53.
+func (r *securityGroupRow) unsafePorts(safePorts map[string][]int) ([]protocolUnsafePorts, error) {
+	result := []protocolUnsafePorts{}
+	for _, protocol := range allProtocols {
+		ranges := r.portRanges[protocol]
+		for _, candidate := range []struct {
+			family string
+			raw    string
+		}{{"v4", ranges.v4}, {"v6", ranges.v6}} {
+			if candidate.raw == "" {
+				continue
+			}
+			mr, err := multirange.FromString(candidate.raw)
+			if err != nil {
+				return nil, errors.Wrapf(err, "Failed to parse %v/%v port range %q", protocol, candidate.family, candidate.raw)
+			}
+			for _, port := range safePorts[protocol] {
+				mr.RemoveElement(port)
+			}
+			if mr.Size() > 0 {
+				result = append(result, protocolUnsafePorts{protocol: protocol, family: candidate.family, ports: mr})
+			}
 		}
-		return mr, nil
 	}
-	return &multirange.MultiRange{}, nil
+	return result, nil
 }
 
-func (r *securityGroupRow) notes(unsafePorts *multirange.MultiRange) []string {
+func unsafePortCount(unsafe []protocolUnsafePorts) int {
+	total := 0
+	for _, u := range unsafe {
+		total += u.ports.Size()
+	}
+	return total
+}
+
+func (r *securityGroupRow) notes(unsafe []protocolUnsafePorts) []string {
 	notes := []string{}
-	if unsafePorts.Size() > 0 && !r.internalOnly {
-		notes = append(notes, fmt.Sprintf("Allows traffic from anywhere on TCP ports (%v)", unsafePorts.Humanize()))
+	if !r.internalOnly {
+		for _, u := range unsafe {
+			cidr := "0.0.0.0/0"
+			if u.family == "v6" {
+				cidr = "::/0"
+			}
+			if u.protocol == protocolAny {
+				notes = append(notes, fmt.Sprintf("Allows all traffic from %v", cidr))
+				continue
+			}
+			notes = append(notes, fmt.Sprintf("Allows %v from %v on ports (%v)", strings.ToUpper(u.protocol), cidr, u.ports.Humanize()))
+		}
+	}
+	if r.isLargePublicBlockV4 {
+		notes = append(notes, "Has IPv4 restrictions, but they let through large ranges")
 	}
-	if r.isLargePublicBlock {
-		notes = append(notes, "Has IP restrictions, but they let through large ranges")
+	if r.isLargePublicBlockV6 {
+		notes = append(notes, "Has IPv6 (::/0) restrictions, but they let through large ranges")
 	}
 	if r.largeRangeCount {
 		notes = append(notes, "Uses a lot of IP Ranges")
@@ -67,8 +124,9 @@ func (r *securityGroupRow) notes(unsafePorts *multirange.MultiRange) []string {
 	if !r.inUse {
 		notes = append(notes, "Not in use")
 	}
-	if len(r.ips) > 0 {
-		notes = append(notes, fmt.Sprintf("Contains %v public IP address(es)", len(r.ips)))
+	totalIps := len(r.ips) + len(r.ipv6s)
+	if totalIps > 0 {
+		notes = append(notes, fmt.Sprintf("Contains %v public IP address(es)", totalIps))
 	} else {
 		notes = append(notes, "No public IP addresses found")
 	}
@@ -76,13 +134,15 @@ func (r *securityGroupRow) notes(unsafePorts *multirange.MultiRange) []string {
 }
 
 type Row struct {
-	Arn       string
-	Name      string
-	Status    string
-	PublicIps []string
-	InUse     bool
-	IsDefault bool
-	Notes     []string
+	Arn             string
+	Name            string
+	Status          string
+	PublicIps       []string
+	PublicIpv6s     []string
+	InUse           bool
+	IsDefault       bool
+	Notes           []string
+	UnsafePortCount int
 }
 
 // Metadata includes information about the report, such as when the data was
@@ -99,11 +159,18 @@ type Report struct {
 	Rows     []Row
 }
 
-var defaultSafePorts = []int{22, 80, 443}
+// defaultSafePortsByProtocol lists the ports considered safe to expose per
+// protocol when the caller doesn't supply its own.
+var defaultSafePortsByProtocol = map[string][]int{
+	protocolTCP: {22, 80, 443},
+	protocolUDP: {53},
+}
 
-// Generate uses a connection string to postgres and a list of designated-safe ports
-// to produce a report assessing the risk of each security group that has been imported.
-func Generate(connectionString string, safePorts []int) (*Report, error) {
+// Generate uses a connection string to postgres and a set of designated-safe
+// ports, keyed by protocol ("tcp", "udp", "icmp", "-1"), to produce a report
+// assessing the risk of each security group that has been imported. A nil
+// policy falls back to DefaultPolicy, sgCheckup's built-in classification rules.
+func Generate(connectionString string, safePorts map[string][]int, policy *Policy) (*Report, error) {
 	db, err := sql.Open("postgres", connectionString)
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed to connect to db")
@@ -123,9 +190,12 @@ func Generate(connectionString string, safePorts []int) (*Report, error) {
 		return nil, errors.Wrap(err, "Failed to run analysis query")
 	}
 	if safePorts == nil {
-		safePorts = defaultSafePorts
+		safePorts = defaultSafePortsByProtocol
 	}
-	reportRows, err := analyzeSecurityGroupResults(rows, safePorts)
+	if policy == nil {
+		policy = DefaultPolicy()
+	}
+	reportRows, err := analyzeSecurityGroupResults(rows, safePorts, policy)
 	if err != nil {
 		return nil, errors.Wrap(err, "Failed to generate report from query results")
 	}
@@ -149,10 +219,21 @@ var statusIndex map[string]int = map[string]int{
 }
 
 func arnRegion(arn string) string {
+	return ArnRegion(arn)
+}
+
+// ArnRegion extracts the AWS region component from a resource ARN.
+func ArnRegion(arn string) string {
 	parts := strings.Split(arn, ":")
 	return parts[3]
 }
 
+// ArnAccount extracts the AWS account ID component from a resource ARN.
+func ArnAccount(arn string) string {
+	parts := strings.Split(arn, ":")
+	return parts[4]
+}
+
 func loadMetadata(db *sql.DB, reportRows []Row) (*Metadata, error) {
 	query, err := loadQuery("most_recent_import")
 	if err != nil {
@@ -173,8 +254,7 @@ func loadMetadata(db *sql.DB, reportRows []Row) (*Metadata, error) {
 		return nil, errors.Wrap(err, "Failed to read most recent import job row")
 	}
 	arn := reportRows[0].Arn
-	parts := strings.Split(arn, ":")
-	accountID := parts[4]
+	accountID := ArnAccount(arn)
 	if strings.HasPrefix(organization, "OrgDummy") {
 		organization = "<NONE>"
 	}
@@ -201,51 +281,48 @@ func sortRowsLess(a, b *Row) bool {
 	return aIndex < bIndex
 }
 
-func analyzeSecurityGroupResults(results []securityGroupRow, safePorts []int) ([]Row, error) {
+func analyzeSecurityGroupResults(results []securityGroupRow, safePorts map[string][]int, policy *Policy) ([]Row, error) {
+	compiledPolicy, err := policy.Compile()
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to compile policy")
+	}
 	reportRows := []Row{}
 	for _, row := range results {
-		var status string
 		unsafePorts, err := row.unsafePorts(safePorts)
 		if err != nil {
 			return nil, errors.Wrap(err, "Failed to calculate unsafe ports")
 		}
-		if row.isDefault {
-			if row.inUse {
-				if row.isRestricted || row.internalOnly || len(row.ips) == 0 {
-					status = "yellow"
-				} else {
-					status = "red"
-				}
-			} else {
-				if row.isRestricted {
-					// best case for default groups, locked down and not in use
-					status = "green"
-				} else {
-					status = "yellow"
-				}
-			}
-		} else {
-			if row.inUse {
-				if row.isRestricted || (!row.isProblematic() && unsafePorts.Size() == 0) {
-					status = "green"
-				} else if len(row.ips) == 0 {
-					status = "yellow"
-				} else {
-					status = "red"
-				}
-			} else {
-				// Not the default, so shouldn't exist if it's not in use
-				status = "yellow"
-			}
+		ctx := &PolicyContext{
+			InUse:            row.inUse,
+			IsDefault:        row.isDefault,
+			IsRestricted:     row.isRestricted,
+			InternalOnly:     row.internalOnly,
+			UnsafePorts:      unsafePortCount(unsafePorts),
+			PublicIPs:        append(append([]string{}, row.ips...), row.ipv6s...),
+			LargePublicBlock: row.isLargePublicBlockV4 || row.isLargePublicBlockV6,
+			LargeRangeCount:  row.largeRangeCount,
+			Region:           arnRegion(row.arn),
+			Account:          ArnAccount(row.arn),
+			Tags:             row.tags,
+		}
+		status, extraNote, err := compiledPolicy.Evaluate(ctx)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to evaluate policy for %v", row.arn)
+		}
+		notes := row.notes(unsafePorts)
+		if extraNote != "" {
+			notes = append(notes, extraNote)
 		}
 		reportRows = append(reportRows, Row{
-			Arn:       row.arn,
-			Name:      row.groupName,
-			Status:    status,
-			PublicIps: row.ips,
-			InUse:     row.inUse,
-			IsDefault: row.isDefault,
-			Notes:     row.notes(unsafePorts),
+			Arn:             row.arn,
+			Name:            row.groupName,
+			Status:          status,
+			PublicIps:       row.ips,
+			PublicIpv6s:     row.ipv6s,
+			InUse:           row.inUse,
+			IsDefault:       row.isDefault,
+			Notes:           notes,
+			UnsafePortCount: unsafePortCount(unsafePorts),
 		})
 	}
 	return reportRows, nil
@@ -277,12 +354,24 @@ func runSecurityGroupQuery(db *sql.DB) ([]securityGroupRow, error) {
 	results := make([]securityGroupRow, 0)
 	for rows.Next() {
 		row := securityGroupRow{}
-		err = rows.Scan(&row.arn, &row.groupName, pq.Array(&row.ips), &row.inUse, &row.isDefault,
-			pq.Array(&row.portRanges),
-			&row.isLargePublicBlock, &row.largeRangeCount, &row.isRestricted, &row.internalOnly)
+		var tcpV4, tcpV6, udpV4, udpV6, icmpV4, icmpV6, anyV4, anyV6 string
+		var tagsJSON []byte
+		err = rows.Scan(&row.arn, &row.groupName, pq.Array(&row.ips), pq.Array(&row.ipv6s), &row.inUse, &row.isDefault,
+			&tcpV4, &tcpV6, &udpV4, &udpV6, &icmpV4, &icmpV6, &anyV4, &anyV6,
+			&row.isLargePublicBlockV4, &row.isLargePublicBlockV6, &row.largeRangeCount, &row.isRestricted, &row.internalOnly,
+			&tagsJSON)
 		if err != nil {
 			return nil, errors.Wrap(err, "Failed to unmarshal a row")
 		}
+		row.portRanges = map[string]protocolPortRanges{
+			protocolTCP:  {v4: tcpV4, v6: tcpV6},
+			protocolUDP:  {v4: udpV4, v6: udpV6},
+			protocolICMP: {v4: icmpV4, v6: icmpV6},
+			protocolAny:  {v4: anyV4, v6: anyV6},
+		}
+		if err := json.Unmarshal(tagsJSON, &row.tags); err != nil {
+			return nil, errors.Wrapf(err, "Failed to unmarshal tags for %v", row.arn)
+		}
 		results = append(results, row)
 	}
 	log.Infof("rows %v", len(results))
@@ -0,0 +1,149 @@
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const sarifSchema = "https://raw.githubusercontent.com/oasis-tcs/sarif-spec/master/Schemata/sarif-schema-2.1.0.json"
+const sarifVersion = "2.1.0"
+
+// sarifUnsafePortsRule / sarifLargePublicBlockRule / sarifDefaultRule are the
+// rule IDs a security group's notes can be classified into.
+const (
+	sarifUnsafePortsRule      = "SG001-unsafe-port-exposure"
+	sarifLargePublicBlockRule = "SG002-large-public-block"
+	sarifDefaultRule          = "SG000-non-compliant"
+)
+
+var sarifRules = []sarifReportingDescriptor{
+	{ID: sarifUnsafePortsRule, ShortDescription: sarifMultiformatMessage{Text: "Security group allows traffic from anywhere on an unsafe port"}},
+	{ID: sarifLargePublicBlockRule, ShortDescription: sarifMultiformatMessage{Text: "Security group's IP restrictions let through large public ranges"}},
+	{ID: sarifDefaultRule, ShortDescription: sarifMultiformatMessage{Text: "Security group does not comply with sgCheckup's default policy"}},
+}
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool    sarifTool     `json:"tool"`
+	Results []sarifResult `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string                     `json:"name"`
+	Rules          []sarifReportingDescriptor `json:"rules"`
+	InformationURI string                     `json:"informationUri,omitempty"`
+}
+
+type sarifReportingDescriptor struct {
+	ID               string                  `json:"id"`
+	ShortDescription sarifMultiformatMessage `json:"shortDescription"`
+}
+
+type sarifMultiformatMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID        string          `json:"ruleId"`
+	Level         string          `json:"level"`
+	Message       sarifMessage    `json:"message"`
+	Locations     []sarifLocation `json:"locations"`
+	BaselineState string          `json:"baselineState,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifLocation struct {
+	LogicalLocations []sarifLogicalLocation `json:"logicalLocations"`
+}
+
+type sarifLogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+}
+
+// SarifRenderer renders a Report as SARIF 2.1.0, so results can be uploaded
+// to GitHub code scanning or other SARIF-consuming tooling.
+type SarifRenderer struct{}
+
+// Render implements Renderer.
+func (s *SarifRenderer) Render(w io.Writer, r *Report) error {
+	log := sarifLog{
+		Schema:  sarifSchema,
+		Version: sarifVersion,
+		Runs: []sarifRun{
+			{
+				Tool: sarifTool{
+					Driver: sarifDriver{
+						Name:           "sgCheckup",
+						Rules:          sarifRules,
+						InformationURI: "https://github.com/ryuheechul/sgCheckup",
+					},
+				},
+				Results: sarifResults(r.Rows),
+			},
+		},
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(log); err != nil {
+		return errors.Wrap(err, "Failed to render SARIF report")
+	}
+	return nil
+}
+
+func sarifResults(rows []Row) []sarifResult {
+	results := make([]sarifResult, 0, len(rows))
+	for _, row := range rows {
+		if row.Status == "green" {
+			continue
+		}
+		results = append(results, sarifResult{
+			RuleID: sarifRuleFor(row),
+			Level:  sarifLevelFor(row.Status),
+			Message: sarifMessage{
+				Text: strings.Join(row.Notes, " "),
+			},
+			Locations: []sarifLocation{
+				{LogicalLocations: []sarifLogicalLocation{{FullyQualifiedName: row.Arn}}},
+			},
+		})
+	}
+	return results
+}
+
+func sarifRuleFor(row Row) string {
+	if row.UnsafePortCount > 0 {
+		return sarifUnsafePortsRule
+	}
+	for _, note := range row.Notes {
+		if strings.Contains(note, "large ranges") || strings.Contains(note, "large public block") {
+			return sarifLargePublicBlockRule
+		}
+	}
+	return sarifDefaultRule
+}
+
+func sarifLevelFor(status string) string {
+	switch status {
+	case "red":
+		return "error"
+	case "yellow":
+		return "warning"
+	default:
+		return "none"
+	}
+}
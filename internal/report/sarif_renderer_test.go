@@ -0,0 +1,69 @@
+package report
+
+import "testing"
+
+func TestSarifResultsSkipsGreenRows(t *testing.T) {
+	rows := []Row{
+		{Arn: "arn:red", Status: "red", Notes: []string{"Allows TCP from 0.0.0.0/0 on ports (22)"}, UnsafePortCount: 1},
+		{Arn: "arn:green", Status: "green", Notes: []string{"No public IP addresses found"}},
+		{Arn: "arn:yellow", Status: "yellow", Notes: []string{"Not in use"}},
+	}
+
+	results := sarifResults(rows)
+
+	if len(results) != 2 {
+		t.Fatalf("expected 2 results (green row skipped), got %d: %+v", len(results), results)
+	}
+	for _, result := range results {
+		if result.Locations[0].LogicalLocations[0].FullyQualifiedName == "arn:green" {
+			t.Errorf("expected green row to be skipped, found a result for it")
+		}
+	}
+}
+
+func TestSarifRuleFor(t *testing.T) {
+	cases := []struct {
+		name string
+		row  Row
+		want string
+	}{
+		{
+			name: "unsafe port exposure takes precedence",
+			row:  Row{UnsafePortCount: 1, Notes: []string{"Uses a lot of IP Ranges"}},
+			want: sarifUnsafePortsRule,
+		},
+		{
+			name: "large public block noted",
+			row:  Row{Notes: []string{"Has IPv4 restrictions, but they let through large ranges"}},
+			want: sarifLargePublicBlockRule,
+		},
+		{
+			name: "falls back to the default rule",
+			row:  Row{Notes: []string{"Not in use"}},
+			want: sarifDefaultRule,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := sarifRuleFor(tc.row); got != tc.want {
+				t.Errorf("expected rule %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestSarifLevelFor(t *testing.T) {
+	cases := []struct {
+		status string
+		want   string
+	}{
+		{"red", "error"},
+		{"yellow", "warning"},
+		{"green", "none"},
+	}
+	for _, tc := range cases {
+		if got := sarifLevelFor(tc.status); got != tc.want {
+			t.Errorf("status %q: expected level %q, got %q", tc.status, tc.want, got)
+		}
+	}
+}
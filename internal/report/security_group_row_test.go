@@ -0,0 +1,124 @@
+package report
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUnsafePorts(t *testing.T) {
+	cases := []struct {
+		name      string
+		row       securityGroupRow
+		safePorts map[string][]int
+		want      []protocolUnsafePorts
+	}{
+		{
+			name: "tcp port not on the safe list is unsafe",
+			row: securityGroupRow{
+				portRanges: map[string]protocolPortRanges{
+					protocolTCP: {v4: "3389"},
+				},
+			},
+			safePorts: map[string][]int{protocolTCP: {22, 80, 443}},
+			want:      []protocolUnsafePorts{{protocol: protocolTCP, family: "v4"}},
+		},
+		{
+			name: "tcp port on the safe list is not unsafe",
+			row: securityGroupRow{
+				portRanges: map[string]protocolPortRanges{
+					protocolTCP: {v4: "22"},
+				},
+			},
+			safePorts: map[string][]int{protocolTCP: {22}},
+			want:      nil,
+		},
+		{
+			name: "udp port safe-listed under udp only is still unsafe for tcp",
+			row: securityGroupRow{
+				portRanges: map[string]protocolPortRanges{
+					protocolTCP: {v4: "53"},
+					protocolUDP: {v4: "53"},
+				},
+			},
+			safePorts: map[string][]int{protocolUDP: {53}},
+			want:      []protocolUnsafePorts{{protocol: protocolTCP, family: "v4"}},
+		},
+		{
+			name: "ipv6 exposure is tracked independently of ipv4",
+			row: securityGroupRow{
+				portRanges: map[string]protocolPortRanges{
+					protocolTCP: {v6: "3389"},
+				},
+			},
+			safePorts: map[string][]int{},
+			want:      []protocolUnsafePorts{{protocol: protocolTCP, family: "v6"}},
+		},
+		{
+			name: "any-protocol exposure is still flagged",
+			row: securityGroupRow{
+				portRanges: map[string]protocolPortRanges{
+					protocolAny: {v4: "0"},
+				},
+			},
+			safePorts: map[string][]int{},
+			want:      []protocolUnsafePorts{{protocol: protocolAny, family: "v4"}},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := tc.row.unsafePorts(tc.safePorts)
+			if err != nil {
+				t.Fatalf("unsafePorts returned error: %v", err)
+			}
+			if len(got) != len(tc.want) {
+				t.Fatalf("expected %d unsafe protocol/family entries, got %d: %+v", len(tc.want), len(got), got)
+			}
+			for i, want := range tc.want {
+				if got[i].protocol != want.protocol || got[i].family != want.family {
+					t.Errorf("entry %d: expected %v/%v, got %v/%v", i, want.protocol, want.family, got[i].protocol, got[i].family)
+				}
+			}
+		})
+	}
+}
+
+func TestSecurityGroupRowNotes(t *testing.T) {
+	cases := []struct {
+		name   string
+		row    securityGroupRow
+		unsafe []protocolUnsafePorts
+		want   []string
+	}{
+		{
+			name: "in use with a public ip and no other findings",
+			row:  securityGroupRow{inUse: true, ips: []string{"1.2.3.4"}},
+			want: []string{"Contains 1 public IP address(es)"},
+		},
+		{
+			name: "not in use and no public ips",
+			row:  securityGroupRow{inUse: false},
+			want: []string{"Not in use", "No public IP addresses found"},
+		},
+		{
+			name: "large ipv4 public block restriction noted",
+			row:  securityGroupRow{inUse: true, isLargePublicBlockV4: true},
+			want: []string{"Has IPv4 restrictions, but they let through large ranges", "No public IP addresses found"},
+		},
+		{
+			name: "internal-only groups don't get unsafe-port notes",
+			row:  securityGroupRow{inUse: true, internalOnly: true},
+			unsafe: []protocolUnsafePorts{{protocol: protocolTCP, family: "v4"}},
+			want: []string{"No public IP addresses found"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			got := tc.row.notes(tc.unsafe)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("expected notes %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"goldfiglabs.com/sgcheckup/internal/report"
+)
+
+// Handler builds the mux this server answers requests on:
+//
+//	GET  /healthz
+//	GET  /              (HTML report, the same page --format html produces)
+//	GET  /api/v1/report
+//	GET  /api/v1/report/rows?status=red
+//	GET  /api/v1/report/rows/{arn}
+//	POST /api/v1/refresh
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", s.handleHealthz)
+	mux.Handle("/", s.authenticated(http.HandlerFunc(s.handleReportHTML)))
+	mux.Handle("/api/v1/report", s.authenticated(http.HandlerFunc(s.handleReport)))
+	mux.Handle("/api/v1/report/rows", s.authenticated(http.HandlerFunc(s.handleRows)))
+	mux.Handle("/api/v1/report/rows/", s.authenticated(http.HandlerFunc(s.handleRow)))
+	mux.Handle("/api/v1/refresh", s.authenticated(http.HandlerFunc(s.handleRefresh)))
+	return mux
+}
+
+func (s *Server) handleHealthz(w http.ResponseWriter, r *http.Request) {
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("ok"))
+}
+
+// handleReportHTML serves the same self-contained HTML report --format html
+// produces, so a browser pointed at the server doesn't need the JSON API.
+func (s *Server) handleReportHTML(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path != "/" {
+		http.NotFound(w, r)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := (&report.HTMLRenderer{}).Render(w, s.current()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func (s *Server) handleReport(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	writeJSON(w, s.current())
+}
+
+func (s *Server) handleRows(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	current := s.current()
+	status := r.URL.Query().Get("status")
+	if status == "" {
+		writeJSON(w, current.Rows)
+		return
+	}
+	matched := make([]report.Row, 0, len(current.Rows))
+	for _, row := range current.Rows {
+		if row.Status == status {
+			matched = append(matched, row)
+		}
+	}
+	writeJSON(w, matched)
+}
+
+func (s *Server) handleRow(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	encodedArn := strings.TrimPrefix(r.URL.Path, "/api/v1/report/rows/")
+	arn, err := url.PathUnescape(encodedArn)
+	if err != nil {
+		http.Error(w, "Invalid arn", http.StatusBadRequest)
+		return
+	}
+	for _, row := range s.current().Rows {
+		if row.Arn == arn {
+			writeJSON(w, row)
+			return
+		}
+	}
+	http.Error(w, "Not found", http.StatusNotFound)
+}
+
+func (s *Server) handleRefresh(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := s.Refresh(); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, s.current())
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.Encode(v)
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"goldfiglabs.com/sgcheckup/internal/report"
+)
+
+func testServer(bearerToken string) *Server {
+	return &Server{
+		config: Config{BearerToken: bearerToken},
+		report: &report.Report{Rows: []report.Row{
+			{Arn: "arn:red", Name: "red-group", Status: "red"},
+			{Arn: "arn:green", Name: "green-group", Status: "green"},
+		}},
+	}
+}
+
+func TestHandleReportHTML(t *testing.T) {
+	s := testServer("")
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), "red-group") {
+		t.Errorf("expected HTML body to contain %q, got %q", "red-group", rec.Body.String())
+	}
+}
+
+func TestHandleRowsFiltersByStatus(t *testing.T) {
+	s := testServer("")
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/report/rows?status=red", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", rec.Code)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "arn:red") {
+		t.Errorf("expected filtered rows to include arn:red, got %q", body)
+	}
+	if strings.Contains(body, "arn:green") {
+		t.Errorf("expected filtered rows to exclude arn:green, got %q", body)
+	}
+}
+
+func TestHandleRowNotFound(t *testing.T) {
+	s := testServer("")
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/report/rows/arn:missing", nil))
+
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("expected status 404, got %d", rec.Code)
+	}
+}
+
+func TestAuthenticatedRequiresBearerToken(t *testing.T) {
+	s := testServer("secret")
+
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v1/report", nil))
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("expected 401 with no Authorization header, got %d", rec.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/v1/report", nil)
+	req.Header.Set("Authorization", "Bearer secret")
+	rec = httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected 200 with a valid bearer token, got %d", rec.Code)
+	}
+}
+
+func TestHealthzIsNeverAuthenticated(t *testing.T) {
+	s := testServer("secret")
+	rec := httptest.NewRecorder()
+	s.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Errorf("expected /healthz to bypass auth, got status %d", rec.Code)
+	}
+}
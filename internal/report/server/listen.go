@@ -0,0 +1,44 @@
+package server
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/pkg/errors"
+)
+
+// TLSConfig configures serving over TLS, optionally requiring a client
+// certificate signed by ClientCAFile (mTLS) instead of, or alongside, a
+// bearer token.
+type TLSConfig struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+}
+
+// ListenAndServe starts serving s.Handler() on addr. When tlsConfig is nil
+// it serves plain HTTP; otherwise it serves HTTPS, requiring a client
+// certificate when tlsConfig.ClientCAFile is set.
+func (s *Server) ListenAndServe(addr string, tlsConfig *TLSConfig) error {
+	httpServer := &http.Server{Addr: addr, Handler: s.Handler()}
+	if tlsConfig == nil {
+		return httpServer.ListenAndServe()
+	}
+	if tlsConfig.ClientCAFile != "" {
+		clientCA, err := ioutil.ReadFile(tlsConfig.ClientCAFile)
+		if err != nil {
+			return errors.Wrap(err, "Failed to read client CA file")
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(clientCA) {
+			return errors.New("Failed to parse client CA file")
+		}
+		httpServer.TLSConfig = &tls.Config{
+			ClientCAs:  pool,
+			ClientAuth: tls.RequireAndVerifyClientCert,
+		}
+	}
+	return httpServer.ListenAndServeTLS(tlsConfig.CertFile, tlsConfig.KeyFile)
+}
@@ -0,0 +1,90 @@
+// Package server runs sgCheckup as a long-lived process: it re-scans on a
+// cron schedule, caches the latest report in memory, and serves it over an
+// HTTP API so dashboards can poll current posture without re-running the
+// whole ETL+query pipeline themselves.
+package server
+
+import (
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/robfig/cron/v3"
+	log "github.com/sirupsen/logrus"
+
+	"goldfiglabs.com/sgcheckup/internal/report"
+)
+
+// Config controls how a Server scans and what it requires of callers.
+type Config struct {
+	ConnectionString string
+	SafePorts        map[string][]int
+	Policy           *report.Policy
+	// Schedule is a standard 5-field cron expression, e.g. "0 * * * *" to
+	// rescan hourly, or a descriptor such as "@every 1h".
+	Schedule string
+	// BearerToken, when non-empty, is required as an "Authorization: Bearer
+	// <token>" header on every request except /healthz.
+	BearerToken string
+}
+
+// Server holds the most recently generated *report.Report and refreshes it
+// on Config.Schedule.
+type Server struct {
+	config Config
+	cron   *cron.Cron
+
+	mu     sync.RWMutex
+	report *report.Report
+}
+
+// New builds a Server and runs an initial scan so it has a report to serve
+// before the first scheduled refresh fires.
+func New(config Config) (*Server, error) {
+	s := &Server{config: config}
+	if err := s.Refresh(); err != nil {
+		return nil, errors.Wrap(err, "Failed initial scan")
+	}
+	return s, nil
+}
+
+// Start schedules recurring refreshes per Config.Schedule. It does not
+// block; call it before handing Handler() to an http.Server.
+func (s *Server) Start() error {
+	s.cron = cron.New()
+	_, err := s.cron.AddFunc(s.config.Schedule, func() {
+		if err := s.Refresh(); err != nil {
+			log.Errorf("Scheduled refresh failed: %v", err)
+		}
+	})
+	if err != nil {
+		return errors.Wrapf(err, "Invalid schedule %q", s.config.Schedule)
+	}
+	s.cron.Start()
+	return nil
+}
+
+// Stop cancels the scheduled refreshes.
+func (s *Server) Stop() {
+	if s.cron != nil {
+		s.cron.Stop()
+	}
+}
+
+// Refresh re-runs the analysis synchronously and replaces the cached report.
+func (s *Server) Refresh() error {
+	r, err := report.Generate(s.config.ConnectionString, s.config.SafePorts, s.config.Policy)
+	if err != nil {
+		return errors.Wrap(err, "Failed to generate report")
+	}
+	s.mu.Lock()
+	s.report = r
+	s.mu.Unlock()
+	return nil
+}
+
+// current returns the cached report for handlers to read.
+func (s *Server) current() *report.Report {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.report
+}
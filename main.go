@@ -0,0 +1,329 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	log "github.com/sirupsen/logrus"
+
+	"goldfiglabs.com/sgcheckup/internal/report"
+	"goldfiglabs.com/sgcheckup/internal/report/prom"
+	"goldfiglabs.com/sgcheckup/internal/report/server"
+)
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "diff" {
+		if err := runDiff(os.Args[2:]); err != nil {
+			log.Fatalf("sgcheckup diff: %v", err)
+		}
+		return
+	}
+	runGenerate()
+}
+
+func runGenerate() {
+	connectionString := flag.String("db", os.Getenv("DATABASE_URL"), "Postgres connection string")
+	tcpSafePortsFlag := flag.String("safe-ports", "22,80,443", "Comma-separated list of TCP ports considered safe to expose")
+	udpSafePortsFlag := flag.String("udp-safe-ports", "53", "Comma-separated list of UDP ports considered safe to expose")
+	serveMetrics := flag.String("serve-metrics", "", "Address to serve Prometheus metrics on, e.g. :9090. Runs sgCheckup as a long-lived process")
+	scanInterval := flag.Duration("scan-interval", 5*time.Minute, "How often to re-run the analysis while --serve-metrics is set")
+	metricsTextfile := flag.String("metrics-textfile", "", "Also write a one-shot node_exporter textfile collector to this path; the report is still rendered")
+	format := flag.String("format", "html", "Output format: html, json, csv, or sarif")
+	output := flag.String("o", "-", "Output file, or - for stdout")
+	flag.StringVar(output, "output", "-", "Output file, or - for stdout (same as -o)")
+	policyPath := flag.String("policy", "", "Path to a YAML policy file overriding sgCheckup's default risk rules")
+	serveAddr := flag.String("serve", "", "Address to serve the HTTP API on, e.g. :8080. Runs sgCheckup as a long-lived service")
+	schedule := flag.String("schedule", "@every 1h", "Cron expression controlling how often --serve re-scans")
+	bearerToken := flag.String("bearer-token", os.Getenv("SGCHECKUP_BEARER_TOKEN"), "Bearer token required of --serve API callers; unset disables auth")
+	tlsCert := flag.String("tls-cert", "", "TLS certificate file for --serve")
+	tlsKey := flag.String("tls-key", "", "TLS key file for --serve")
+	tlsClientCA := flag.String("tls-client-ca", "", "CA file for verifying client certificates (mTLS) on --serve")
+	saveDir := flag.String("save-dir", "", "Directory to also save this report's JSON to, for later sgcheckup diff runs")
+	saveDB := flag.Bool("save-db", false, "Also persist this report to the sgcheckup_reports table in the --db database, for later sgcheckup diff --db runs")
+	flag.Parse()
+
+	safePorts, err := parseSafePortsByProtocol(*tcpSafePortsFlag, *udpSafePortsFlag)
+	if err != nil {
+		log.Fatalf("Invalid safe ports: %v", err)
+	}
+	policy, err := loadPolicy(*policyPath)
+	if err != nil {
+		log.Fatalf("Invalid --policy: %v", err)
+	}
+
+	if *serveAddr != "" {
+		if err := serveForever(*connectionString, safePorts, policy, *schedule, *serveAddr, *bearerToken, *tlsCert, *tlsKey, *tlsClientCA); err != nil {
+			log.Fatalf("Failed serving HTTP API: %v", err)
+		}
+		return
+	}
+
+	if *serveMetrics != "" {
+		if err := serveMetricsForever(*connectionString, safePorts, policy, *serveMetrics, *scanInterval); err != nil {
+			log.Fatalf("Failed serving metrics: %v", err)
+		}
+		return
+	}
+
+	r, err := report.Generate(*connectionString, safePorts, policy)
+	if err != nil {
+		log.Fatalf("Failed to generate report: %v", err)
+	}
+
+	if *metricsTextfile != "" {
+		if err := prom.WriteTextfile(r, *metricsTextfile); err != nil {
+			log.Fatalf("Failed to write metrics textfile: %v", err)
+		}
+	}
+
+	if *saveDir != "" {
+		if _, err := report.SaveJSON(r, *saveDir); err != nil {
+			log.Fatalf("Failed to save report: %v", err)
+		}
+	}
+
+	if *saveDB {
+		if err := saveReportToDB(*connectionString, r); err != nil {
+			log.Fatalf("Failed to save report to db: %v", err)
+		}
+	}
+
+	if err := renderReportTo(r, *format, *output); err != nil {
+		log.Fatalf("Failed to render report: %v", err)
+	}
+}
+
+// saveReportToDB opens its own connection to persist r, independent of the
+// connection report.Generate used, since Generate closes its own when it returns.
+func saveReportToDB(connectionString string, r *report.Report) error {
+	db, err := sql.Open("postgres", connectionString)
+	if err != nil {
+		return errors.Wrap(err, "Failed to connect to db")
+	}
+	defer db.Close()
+	return report.SaveToDB(db, r)
+}
+
+// loadReportFromDB opens connectionString and loads the most recently
+// --save-db'd report for account, for `sgcheckup diff --db`.
+func loadReportFromDB(connectionString string, account string) (*report.Report, error) {
+	db, err := sql.Open("postgres", connectionString)
+	if err != nil {
+		return nil, errors.Wrap(err, "Failed to connect to db")
+	}
+	defer db.Close()
+	return report.LoadFromDB(db, account)
+}
+
+// runDiff implements `sgcheckup diff prev.json curr.json`, comparing two
+// previously saved or rendered (--format json) reports. With --db and
+// --account, prev is instead the most recently saved --save-db report for
+// that account.
+func runDiff(args []string) error {
+	flags := flag.NewFlagSet("diff", flag.ExitOnError)
+	format := flags.String("format", "html", "Output format: html, json, or sarif")
+	output := flags.String("o", "-", "Output file, or - for stdout")
+	flags.StringVar(output, "output", "-", "Output file, or - for stdout (same as -o)")
+	dbConnectionString := flags.String("db", "", "Postgres connection string to load the previous report from, keyed by --account, instead of a prev.json argument")
+	account := flags.String("account", "", "AWS account ID whose most recently --save-db'd report to diff against; required with --db")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	var prev *report.Report
+	var err error
+	if *dbConnectionString != "" {
+		if *account == "" {
+			return errors.New("--account is required with --db")
+		}
+		if flags.NArg() != 1 {
+			return errors.New("usage: sgcheckup diff --db <connection> --account <id> [flags] curr.json")
+		}
+		prev, err = loadReportFromDB(*dbConnectionString, *account)
+		if err != nil {
+			return err
+		}
+	} else {
+		if flags.NArg() != 2 {
+			return errors.New("usage: sgcheckup diff [flags] prev.json curr.json")
+		}
+		prev, err = report.LoadJSON(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+	}
+	curr, err := report.LoadJSON(flags.Arg(flags.NArg() - 1))
+	if err != nil {
+		return err
+	}
+	diffReport := report.Diff(prev, curr)
+	renderer, err := report.DiffRendererFor(*format)
+	if err != nil {
+		return err
+	}
+	out := os.Stdout
+	if *output != "-" {
+		f, err := os.Create(*output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	return renderer.RenderDiff(out, diffReport)
+}
+
+func renderReportTo(r *report.Report, format string, output string) error {
+	renderer, err := report.RendererFor(format)
+	if err != nil {
+		return err
+	}
+	out := os.Stdout
+	if output != "-" {
+		f, err := os.Create(output)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		out = f
+	}
+	return renderer.Render(out, r)
+}
+
+// loadPolicy loads the policy named by --policy, or falls back to
+// report.DefaultPolicy when no path was given.
+func loadPolicy(path string) (*report.Policy, error) {
+	if path == "" {
+		return report.DefaultPolicy(), nil
+	}
+	return report.LoadPolicy(path)
+}
+
+func parseSafePortsByProtocol(tcpFlag string, udpFlag string) (map[string][]int, error) {
+	tcpPorts, err := parsePorts(tcpFlag)
+	if err != nil {
+		return nil, err
+	}
+	udpPorts, err := parsePorts(udpFlag)
+	if err != nil {
+		return nil, err
+	}
+	return map[string][]int{
+		"tcp": tcpPorts,
+		"udp": udpPorts,
+	}, nil
+}
+
+func parsePorts(flagValue string) ([]int, error) {
+	parts := strings.Split(flagValue, ",")
+	ports := make([]int, 0, len(parts))
+	for _, part := range parts {
+		port, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return nil, err
+		}
+		ports = append(ports, port)
+	}
+	return ports, nil
+}
+
+// serveForever runs sgCheckup as a long-lived service: an HTTP API backed by
+// a report that re-scans on schedule.
+func serveForever(connectionString string, safePorts map[string][]int, policy *report.Policy, schedule string, addr string, bearerToken string, tlsCert string, tlsKey string, tlsClientCA string) error {
+	s, err := server.New(server.Config{
+		ConnectionString: connectionString,
+		SafePorts:        safePorts,
+		Policy:           policy,
+		Schedule:         schedule,
+		BearerToken:      bearerToken,
+	})
+	if err != nil {
+		return err
+	}
+	if err := s.Start(); err != nil {
+		return err
+	}
+	var tlsConfig *server.TLSConfig
+	if tlsCert != "" {
+		tlsConfig = &server.TLSConfig{CertFile: tlsCert, KeyFile: tlsKey, ClientCAFile: tlsClientCA}
+	}
+	log.Infof("serving HTTP API on %v", addr)
+	return s.ListenAndServe(addr, tlsConfig)
+}
+
+// serveMetricsForever re-runs the analysis on scanInterval, exposing the
+// latest result as Prometheus metrics on addr until the process exits.
+func serveMetricsForever(connectionString string, safePorts map[string][]int, policy *report.Policy, addr string, scanInterval time.Duration) error {
+	collector := newReloadingCollector(connectionString, safePorts, policy, scanInterval)
+	registry := prometheus.NewRegistry()
+	if err := registry.Register(collector); err != nil {
+		return err
+	}
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	log.Infof("serving metrics on %v", addr)
+	return http.ListenAndServe(addr, mux)
+}
+
+// reloadingCollector re-runs report.Generate on a timer and delegates
+// Prometheus collection to the most recent successful report, so a scrape
+// never blocks on the full ETL+query pipeline.
+type reloadingCollector struct {
+	connectionString string
+	safePorts        map[string][]int
+	policy           *report.Policy
+
+	mu        sync.RWMutex
+	collector *prom.Collector
+}
+
+func newReloadingCollector(connectionString string, safePorts map[string][]int, policy *report.Policy, scanInterval time.Duration) *reloadingCollector {
+	c := &reloadingCollector{connectionString: connectionString, safePorts: safePorts, policy: policy}
+	c.refresh()
+	go func() {
+		for range time.Tick(scanInterval) {
+			c.refresh()
+		}
+	}()
+	return c
+}
+
+func (c *reloadingCollector) refresh() {
+	r, err := report.Generate(c.connectionString, c.safePorts, c.policy)
+	if err != nil {
+		log.Errorf("Failed to refresh report: %v", err)
+		return
+	}
+	c.mu.Lock()
+	c.collector = prom.NewCollector(r)
+	c.mu.Unlock()
+}
+
+func (c *reloadingCollector) current() *prom.Collector {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.collector
+}
+
+// Describe implements prometheus.Collector.
+func (c *reloadingCollector) Describe(ch chan<- *prometheus.Desc) {
+	if current := c.current(); current != nil {
+		current.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (c *reloadingCollector) Collect(ch chan<- prometheus.Metric) {
+	if current := c.current(); current != nil {
+		current.Collect(ch)
+	}
+}